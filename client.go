@@ -11,9 +11,12 @@ import (
 	"testing"
 	"time"
 
+	"github.com/cenkalti/backoff/v4"
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/container"
 	"github.com/docker/docker/api/types/mount"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/api/types/strslice"
 	"github.com/docker/docker/client"
 	"github.com/docker/go-connections/nat"
 	"github.com/google/uuid"
@@ -23,8 +26,6 @@ import (
 
 type ContainerPort int
 
-const ContainerToLocalhostDNS = "docker.for.mac.localhost"
-
 func RequireDocker(t *testing.T) {
 	if !DockerExists() {
 		t.Skipf("Docker tests ignored because either docker isn't installed or the docker daemon isn't running")
@@ -74,8 +75,11 @@ func StartContainer(req NewContainerRequest, prefix string) (*DockerContainer, e
 	}
 
 	c := container.Config{
-		Image: req.Image,
-		Cmd:   req.Args,
+		Image:      req.Image,
+		Cmd:        req.Args,
+		Hostname:   req.Hostname,
+		User:       req.User,
+		WorkingDir: req.WorkingDir,
 	}
 
 	// set our env vars
@@ -83,8 +87,37 @@ func StartContainer(req NewContainerRequest, prefix string) (*DockerContainer, e
 		c.Env = append(c.Env, fmt.Sprintf("%s=%s", k, v))
 	}
 
+	c.Labels = make(map[string]string, len(req.Labels))
+	for k, v := range req.Labels {
+		c.Labels[k] = v
+	}
+
+	if !req.SkipReaper {
+		labels, err := sessionLabels(context.Background(), client)
+		if err != nil {
+			return nil, errors.Wrap(err, "preparing reaper session")
+		}
+
+		for k, v := range labels {
+			c.Labels[k] = v
+		}
+	}
+
 	h := container.HostConfig{
 		Mounts: binds,
+		Resources: container.Resources{
+			Memory:    req.Memory,
+			CPUShares: req.CPUShares,
+			CPUQuota:  req.CPUQuota,
+			PidsLimit: req.PidsLimit,
+		},
+		Runtime:     req.Runtime,
+		Privileged:  req.Privileged,
+		CapAdd:      strslice.StrSlice(req.CapAdd),
+		CapDrop:     strslice.StrSlice(req.CapDrop),
+		SecurityOpt: req.SecurityOpt,
+		Tmpfs:       req.Tmpfs,
+		ShmSize:     req.ShmSize,
 	}
 
 	portMaps := make(map[int]int)
@@ -121,22 +154,58 @@ func StartContainer(req NewContainerRequest, prefix string) (*DockerContainer, e
 		}
 	}
 
-	res, err := client.ContainerCreate(context.Background(), &c, &h, nil, prefix+"-"+uuid.New().String())
+	// the Docker API only allows one network to be attached at container-create time; any
+	// additional ones are joined afterwards via NetworkConnect.
+	var networkingConfig *network.NetworkingConfig
+	if len(req.Networks) > 0 {
+		networkingConfig = &network.NetworkingConfig{
+			EndpointsConfig: map[string]*network.EndpointSettings{
+				req.Networks[0]: {Aliases: req.NetworkAliases[req.Networks[0]]},
+			},
+		}
+	}
+
+	res, err := client.ContainerCreate(context.Background(), &c, &h, networkingConfig, prefix+"-"+uuid.New().String())
 	if err != nil {
 		return nil, err
 	}
 
+	if len(req.Networks) > 1 {
+		for _, netName := range req.Networks[1:] {
+			err := client.NetworkConnect(context.Background(), netName, res.ID, &network.EndpointSettings{
+				Aliases: req.NetworkAliases[netName],
+			})
+			if err != nil {
+				return nil, errors.Wrapf(err, "attaching container to network %s", netName)
+			}
+		}
+	}
+
 	if err := client.ContainerStart(context.Background(), res.ID, types.ContainerStartOptions{}); err != nil {
 		return nil, err
 	}
 
 	logrus.Infof("Started container %s with id %s", req.Image, res.ID)
 
-	return &DockerContainer{
+	d := &DockerContainer{
 		portMappings: portMaps,
 		id:           res.ID,
 		client:       client,
-	}, nil
+	}
+
+	registerContainer(d)
+
+	if req.WaitFor != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), defaultWaitTimeout)
+		defer cancel()
+
+		if err := req.WaitFor.WaitUntilReady(ctx, d); err != nil {
+			d.Close()
+			return nil, errors.Wrap(err, "container never became ready")
+		}
+	}
+
+	return d, nil
 }
 
 func pullImage(client *client.Client, image string) error {
@@ -181,6 +250,57 @@ type NewContainerRequest struct {
 	PullAlways   bool
 	VolumeMounts map[string]string
 	EnvVars      map[string]string
+
+	// WaitFor, if set, is run by StartContainer before it returns, so that callers get back
+	// a container that is actually ready to use rather than merely running.
+	WaitFor WaitStrategy
+
+	// SkipReaper opts this container out of the reaper-managed session, so it won't be
+	// removed by PruneSession or by the reaper sidecar when the process exits.
+	SkipReaper bool
+
+	// Networks attaches the container to each named user-defined network, so it can reach
+	// (and be reached by) other containers on those networks by name.
+	Networks []string
+	// NetworkAliases are extra DNS names to register for this container on a given network,
+	// keyed by network name.
+	NetworkAliases map[string][]string
+	// Hostname sets the container's hostname. Defaults to the daemon-assigned value.
+	Hostname string
+
+	// Name identifies this container within a StartGroup. Required when used with
+	// StartGroup, ignored by a standalone StartContainer call.
+	Name string
+	// DependsOn lists the Name of other containers in the same StartGroup that must be
+	// started (and have passed their own WaitFor strategy) before this one is started.
+	DependsOn []string
+
+	// Memory caps the container's memory in bytes. Zero means unlimited.
+	Memory int64
+	// CPUShares sets the relative CPU weight versus other containers.
+	CPUShares int64
+	// CPUQuota is the number of microseconds per 100ms CPU period the container is allowed
+	// to run for.
+	CPUQuota int64
+	// PidsLimit caps the number of processes the container may run, nil for unlimited.
+	PidsLimit *int64
+
+	// Runtime selects an alternate OCI runtime, e.g. "runsc" to run under gVisor instead of
+	// the default runc.
+	Runtime string
+
+	Privileged  bool
+	CapAdd      []string
+	CapDrop     []string
+	SecurityOpt []string
+
+	// Tmpfs mounts an in-memory tmpfs at each key path, with the mount options given by its value.
+	Tmpfs   map[string]string
+	ShmSize int64
+
+	User       string
+	WorkingDir string
+	Labels     map[string]string
 }
 
 type DockerContainer struct {
@@ -192,7 +312,12 @@ type DockerContainer struct {
 // WaitForPortToOpen queries the container port and checks to see when it's open
 func (d DockerContainer) WaitForPortToOpen(port ContainerPort, timeout time.Duration) error {
 	return waitFor(func() error {
-		conn, err := net.DialTimeout("tcp", net.JoinHostPort("", strconv.Itoa(int(port))), 50*time.Millisecond)
+		host, err := d.Host(context.Background())
+		if err != nil {
+			return err
+		}
+
+		conn, err := net.DialTimeout("tcp", net.JoinHostPort(host, strconv.Itoa(int(port))), 50*time.Millisecond)
 		if err == nil {
 			_ = conn.Close()
 			return nil
@@ -232,21 +357,14 @@ func (d DockerContainer) WaitForLogLine(text string, timeout time.Duration) erro
 }
 
 func waitFor(predicate func() error, timeout time.Duration) error {
-	end := time.Now().Add(timeout)
-
-	ticker := time.NewTicker(50 * time.Millisecond)
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
 
-	for range ticker.C {
-		if err := predicate(); err == nil {
-			return nil
-		}
-
-		if time.Now().After(end) {
-			ticker.Stop()
-		}
+	if err := backoff.Retry(predicate, newBackOff(ctx)); err != nil {
+		return errors.Wrap(err, "Predicate never succeeded")
 	}
 
-	return errors.New("Predicate never succeeded")
+	return nil
 }
 
 func (d DockerContainer) PortMapping(port int) ContainerPort {
@@ -258,6 +376,8 @@ func (d DockerContainer) Close() {
 }
 
 func (d DockerContainer) CloseWithTimeout(timeout time.Duration) {
+	defer unregisterContainer(&d)
+
 	// try and graceful stop, if it doesn't, just kill it
 	if err := d.client.ContainerStop(context.Background(), d.id, &timeout); err != nil {
 		if err := d.client.ContainerKill(context.Background(), d.id, "KILL"); err != nil {
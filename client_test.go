@@ -58,9 +58,14 @@ func ExampleStartContainer() {
 	// Don't forget to close the container when we're done with the test!
 	defer container.Close()
 
-	// Find out which local port the container's port 6379 got mapped to
-	localPort := container.PortMapping(6379)
-	fmt.Printf("so, apparently redis is up on local port: %d\n", localPort)
+	// Find out where the container's port 6379 got mapped to. Endpoint resolves the
+	// daemon host for you, so this works against Docker Desktop, a remote engine, or a
+	// daemon reachable only from inside another container.
+	endpoint, err := container.Endpoint(6379, "redis")
+	if err != nil {
+		panic(err)
+	}
+	fmt.Printf("so, apparently redis is up at: %s\n", endpoint)
 
-	// connect to the above port, and do stuff with redis!
+	// connect to the above endpoint, and do stuff with redis!
 }
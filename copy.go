@@ -0,0 +1,100 @@
+package docker
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/docker/docker/api/types"
+	"github.com/pkg/errors"
+)
+
+// CopyFileToContainer tars a single file in memory and copies it into the container at
+// containerPath, with the given file mode. This is how to get a TLS cert or a SQL init
+// script into a container without baking a custom image for it.
+func (d DockerContainer) CopyFileToContainer(ctx context.Context, hostPath, containerPath string, mode int64) error {
+	content, err := ioutil.ReadFile(hostPath)
+	if err != nil {
+		return errors.Wrapf(err, "reading %s", hostPath)
+	}
+
+	archive, err := tarFiles(map[string][]byte{containerPath: content}, mode)
+	if err != nil {
+		return err
+	}
+
+	return d.client.CopyToContainer(ctx, d.id, "/", archive, types.CopyToContainerOptions{})
+}
+
+// CopyDirToContainer recursively tars hostDirPath and copies its contents into the
+// container under containerDirPath, preserving the relative directory structure.
+func (d DockerContainer) CopyDirToContainer(ctx context.Context, hostDirPath, containerDirPath string, mode int64) error {
+	files := make(map[string][]byte)
+
+	err := filepath.Walk(hostDirPath, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if info.IsDir() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(hostDirPath, path)
+		if err != nil {
+			return err
+		}
+
+		content, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		files[filepath.Join(containerDirPath, rel)] = content
+
+		return nil
+	})
+	if err != nil {
+		return errors.Wrapf(err, "walking %s", hostDirPath)
+	}
+
+	archive, err := tarFiles(files, mode)
+	if err != nil {
+		return err
+	}
+
+	return d.client.CopyToContainer(ctx, d.id, "/", archive, types.CopyToContainerOptions{})
+}
+
+// tarFiles builds an in-memory tar archive containing each of the given absolute container
+// paths with the given file mode.
+func tarFiles(files map[string][]byte, mode int64) (*bytes.Reader, error) {
+	var buf bytes.Buffer
+	w := tar.NewWriter(&buf)
+
+	for path, content := range files {
+		header := &tar.Header{
+			Name: strings.TrimPrefix(path, "/"),
+			Mode: mode,
+			Size: int64(len(content)),
+		}
+
+		if err := w.WriteHeader(header); err != nil {
+			return nil, errors.Wrapf(err, "writing tar header for %s", path)
+		}
+
+		if _, err := w.Write(content); err != nil {
+			return nil, errors.Wrapf(err, "writing tar content for %s", path)
+		}
+	}
+
+	if err := w.Close(); err != nil {
+		return nil, errors.Wrap(err, "closing tar archive")
+	}
+
+	return bytes.NewReader(buf.Bytes()), nil
+}
@@ -0,0 +1,31 @@
+package docker
+
+import (
+	"archive/tar"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTarFiles(t *testing.T) {
+	archive, err := tarFiles(map[string][]byte{
+		"/etc/app/config.yml": []byte("key: value"),
+	}, 0644)
+	assert.NoError(t, err)
+
+	r := tar.NewReader(archive)
+
+	header, err := r.Next()
+	assert.NoError(t, err)
+	assert.Equal(t, "etc/app/config.yml", header.Name)
+	assert.EqualValues(t, 0644, header.Mode)
+	assert.Equal(t, int64(len("key: value")), header.Size)
+
+	content, err := io.ReadAll(r)
+	assert.NoError(t, err)
+	assert.Equal(t, "key: value", string(content))
+
+	_, err = r.Next()
+	assert.Equal(t, io.EOF, err)
+}
@@ -0,0 +1,91 @@
+package docker
+
+import (
+	"bytes"
+	"context"
+	"io"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/pkg/stdcopy"
+)
+
+// ExecOptions configures how a command is run inside a container via Exec.
+type ExecOptions struct {
+	Env        []string
+	WorkingDir string
+	User       string
+}
+
+// Exec runs cmd inside the container and waits for it to finish, returning its exit code
+// and its demuxed stdout/stderr. It's a common building block for both test assertions and
+// the ForExec wait strategy.
+func (d DockerContainer) Exec(ctx context.Context, cmd []string, opts ExecOptions) (exitCode int, stdout, stderr []byte, err error) {
+	created, err := d.client.ContainerExecCreate(ctx, d.id, types.ExecConfig{
+		Cmd:          cmd,
+		Env:          opts.Env,
+		WorkingDir:   opts.WorkingDir,
+		User:         opts.User,
+		AttachStdout: true,
+		AttachStderr: true,
+	})
+	if err != nil {
+		return 0, nil, nil, err
+	}
+
+	attached, err := d.client.ContainerExecAttach(ctx, created.ID, types.ExecStartCheck{})
+	if err != nil {
+		return 0, nil, nil, err
+	}
+	defer attached.Close()
+
+	var stdoutBuf, stderrBuf bytes.Buffer
+	if _, err := stdcopy.StdCopy(&stdoutBuf, &stderrBuf, attached.Reader); err != nil {
+		return 0, nil, nil, err
+	}
+
+	inspect, err := d.client.ContainerExecInspect(ctx, created.ID)
+	if err != nil {
+		return 0, nil, nil, err
+	}
+
+	return inspect.ExitCode, stdoutBuf.Bytes(), stderrBuf.Bytes(), nil
+}
+
+// Logs returns a reader over the container's combined stdout/stderr, demuxed into a single
+// plain byte stream, that keeps streaming new lines as they're written until the caller
+// closes it.
+func (d DockerContainer) Logs(ctx context.Context) (io.ReadCloser, error) {
+	raw, err := d.client.ContainerLogs(ctx, d.id, types.ContainerLogsOptions{
+		ShowStdout: true,
+		ShowStderr: true,
+		Follow:     true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	pr, pw := io.Pipe()
+
+	go func() {
+		_, err := stdcopy.StdCopy(pw, pw, raw)
+		_ = pw.CloseWithError(err)
+	}()
+
+	return &demuxedLogs{raw: raw, pipe: pr}, nil
+}
+
+// demuxedLogs merges the stdout/stderr frames off the underlying docker log stream into a
+// single io.ReadCloser, closing both the pipe and the raw stream together.
+type demuxedLogs struct {
+	raw  io.ReadCloser
+	pipe *io.PipeReader
+}
+
+func (l *demuxedLogs) Read(p []byte) (int, error) {
+	return l.pipe.Read(p)
+}
+
+func (l *demuxedLogs) Close() error {
+	_ = l.raw.Close()
+	return l.pipe.Close()
+}
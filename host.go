@@ -0,0 +1,90 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/docker/docker/api/types"
+	"github.com/pkg/errors"
+)
+
+// ContainerToLocalhostDNS is the DNS name a process *inside* a container can use to reach
+// services published on the Docker host's loopback interface. It's only meaningful on
+// Docker Desktop (Mac/Windows); on Linux it has no special meaning.
+//
+// Deprecated: prefer DockerContainer.Host, which resolves the address the *test process*
+// (not the container) should use to reach a published port, and works against remote and
+// rootless daemons as well as Docker Desktop.
+const ContainerToLocalhostDNS = "docker.for.mac.localhost"
+
+// Host resolves the address that this process should use to reach ports published by the
+// container, mirroring testcontainers-go's daemonHost resolution:
+//
+//  1. TC_HOST, if set, always wins.
+//  2. DOCKER_HOST unset or a unix:// socket, and this process is not itself running inside
+//     a container, means the daemon is local: use localhost.
+//  3. DOCKER_HOST as tcp://host:port: use the host from that URL.
+//  4. Otherwise (e.g. this process is itself running inside a container), fall back to the
+//     gateway address of the bridge network, since published ports are reachable there.
+func (d DockerContainer) Host(ctx context.Context) (string, error) {
+	if host := os.Getenv("TC_HOST"); host != "" {
+		return host, nil
+	}
+
+	dockerHost := os.Getenv("DOCKER_HOST")
+	if dockerHost == "" || strings.HasPrefix(dockerHost, "unix://") {
+		if !runningInContainer() {
+			return "localhost", nil
+		}
+	} else {
+		u, err := url.Parse(dockerHost)
+		if err != nil {
+			return "", errors.Wrapf(err, "parsing DOCKER_HOST %q", dockerHost)
+		}
+
+		if u.Scheme == "tcp" {
+			return u.Hostname(), nil
+		}
+	}
+
+	network, err := d.client.NetworkInspect(ctx, "bridge", types.NetworkInspectOptions{})
+	if err != nil {
+		return "", errors.Wrap(err, "inspecting bridge network for gateway address")
+	}
+
+	for _, ipam := range network.IPAM.Config {
+		if ipam.Gateway != "" {
+			return ipam.Gateway, nil
+		}
+	}
+
+	return "localhost", nil
+}
+
+// runningInContainer reports whether this process is itself running inside a container
+// (and therefore can't reach the daemon's published ports via localhost). It's a var
+// rather than a plain func so tests can stub it out without a real /.dockerenv.
+var runningInContainer = func() bool {
+	_, err := os.Stat("/.dockerenv")
+
+	return err == nil
+}
+
+// Endpoint returns a fully qualified "proto://host:port" URL for a container port, using the
+// resolved Host and the port's assigned host mapping.
+func (d DockerContainer) Endpoint(port int, proto string) (string, error) {
+	host, err := d.Host(context.Background())
+	if err != nil {
+		return "", err
+	}
+
+	mapped, ok := d.portMappings[port]
+	if !ok {
+		return "", errors.Errorf("port %d was not published by this container", port)
+	}
+
+	return fmt.Sprintf("%s://%s:%d", proto, host, mapped), nil
+}
@@ -0,0 +1,57 @@
+package docker
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHostEnvPrecedence(t *testing.T) {
+	tests := []struct {
+		name       string
+		tcHost     string
+		dockerHost string
+		expected   string
+	}{
+		{
+			name:     "TC_HOST always wins",
+			tcHost:   "tc-host",
+			expected: "tc-host",
+		},
+		{
+			name:     "empty DOCKER_HOST means local daemon",
+			expected: "localhost",
+		},
+		{
+			name:       "unix:// DOCKER_HOST means local daemon",
+			dockerHost: "unix:///var/run/docker.sock",
+			expected:   "localhost",
+		},
+		{
+			name:       "tcp:// DOCKER_HOST uses the URL host",
+			dockerHost: "tcp://remote-daemon:2375",
+			expected:   "remote-daemon",
+		},
+		{
+			name:       "tcp:// DOCKER_HOST with no port still resolves the host",
+			dockerHost: "tcp://1.2.3.4",
+			expected:   "1.2.3.4",
+		},
+	}
+
+	originalRunningInContainer := runningInContainer
+	defer func() { runningInContainer = originalRunningInContainer }()
+	runningInContainer = func() bool { return false }
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("TC_HOST", tt.tcHost)
+			t.Setenv("DOCKER_HOST", tt.dockerHost)
+
+			host, err := DockerContainer{}.Host(context.Background())
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, host)
+		})
+	}
+}
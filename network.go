@@ -0,0 +1,186 @@
+package docker
+
+import (
+	"context"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/client"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// NetworkOptions configures a network created by CreateNetwork.
+type NetworkOptions struct {
+	// Driver defaults to "bridge" when empty.
+	Driver string
+	Labels map[string]string
+}
+
+// Network is a user-defined Docker network that containers can be attached to so they can
+// reach each other by name, the way `docker network create` + `--network` does on the CLI.
+type Network struct {
+	id     string
+	name   string
+	client *client.Client
+}
+
+// CreateNetwork creates a new Docker network with the given name.
+func CreateNetwork(name string, opts NetworkOptions) (*Network, error) {
+	cli, err := client.NewEnvClient()
+	if err != nil {
+		return nil, err
+	}
+
+	driver := opts.Driver
+	if driver == "" {
+		driver = "bridge"
+	}
+
+	res, err := cli.NetworkCreate(context.Background(), name, types.NetworkCreate{
+		Driver: driver,
+		Labels: opts.Labels,
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "creating network %s", name)
+	}
+
+	return &Network{id: res.ID, name: name, client: cli}, nil
+}
+
+// Remove deletes the network.
+func (n *Network) Remove() error {
+	err := n.client.NetworkRemove(context.Background(), n.id)
+
+	if closeErr := n.client.Close(); closeErr != nil {
+		logrus.Debugf("Unable to close docker client %v", closeErr)
+	}
+
+	return err
+}
+
+// StartGroup starts a set of containers on a shared, freshly created network, so they can
+// discover each other by name - the minimum needed for e.g. a multi-broker Kafka cluster
+// test. Containers are started in the order implied by their DependsOn fields, each one's
+// WaitFor strategy is run before the next one starts, and if anything fails the whole group
+// (containers already started, and the network) is torn down before returning the error.
+//
+// The returned *Network is the caller's responsibility to Remove() once the group is torn
+// down; it is labeled with the reaper session so it's still cleaned up if the caller forgets.
+func StartGroup(reqs []NewContainerRequest, prefix string) ([]*DockerContainer, *Network, error) {
+	order, err := dependencyOrder(reqs)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	cli, err := client.NewEnvClient()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	labels, err := sessionLabels(context.Background(), cli)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "preparing reaper session")
+	}
+
+	if err := cli.Close(); err != nil {
+		logrus.Debugf("Unable to close docker client %v", err)
+	}
+
+	netw, err := CreateNetwork(prefix+"-"+uuid.New().String(), NetworkOptions{Labels: labels})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var started []*DockerContainer
+
+	teardown := func() {
+		for _, c := range started {
+			c.Close()
+		}
+
+		if err := netw.Remove(); err != nil {
+			logrus.Debugf("Unable to remove group network %v", err)
+		}
+	}
+
+	for _, req := range order {
+		req.Networks = append([]string{netw.name}, req.Networks...)
+
+		if req.NetworkAliases == nil {
+			req.NetworkAliases = map[string][]string{}
+		}
+		req.NetworkAliases[netw.name] = append(req.NetworkAliases[netw.name], req.Name)
+
+		if req.Hostname == "" {
+			req.Hostname = req.Name
+		}
+
+		c, err := StartContainer(req, prefix+"-"+req.Name)
+		if err != nil {
+			teardown()
+			return nil, nil, errors.Wrapf(err, "starting group member %s", req.Name)
+		}
+
+		started = append(started, c)
+	}
+
+	return started, netw, nil
+}
+
+// dependencyOrder topologically sorts reqs by their DependsOn (referencing each other's
+// Name), so StartGroup can bring them up in an order where every dependency is already
+// running.
+func dependencyOrder(reqs []NewContainerRequest) ([]NewContainerRequest, error) {
+	byName := make(map[string]NewContainerRequest, len(reqs))
+	for _, req := range reqs {
+		if req.Name == "" {
+			return nil, errors.New("every container in a group must have a Name")
+		}
+
+		if _, exists := byName[req.Name]; exists {
+			return nil, errors.Errorf("duplicate container name %q in group", req.Name)
+		}
+
+		byName[req.Name] = req
+	}
+
+	var order []NewContainerRequest
+	visited := make(map[string]int) // 0 = unvisited, 1 = in progress, 2 = done
+
+	var visit func(name string) error
+	visit = func(name string) error {
+		switch visited[name] {
+		case 2:
+			return nil
+		case 1:
+			return errors.Errorf("dependency cycle detected at %q", name)
+		}
+
+		visited[name] = 1
+
+		req, ok := byName[name]
+		if !ok {
+			return errors.Errorf("unknown DependsOn target %q", name)
+		}
+
+		for _, dep := range req.DependsOn {
+			if err := visit(dep); err != nil {
+				return err
+			}
+		}
+
+		visited[name] = 2
+		order = append(order, req)
+
+		return nil
+	}
+
+	for _, req := range reqs {
+		if err := visit(req.Name); err != nil {
+			return nil, err
+		}
+	}
+
+	return order, nil
+}
@@ -0,0 +1,95 @@
+package docker
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func namesOf(reqs []NewContainerRequest) []string {
+	names := make([]string, len(reqs))
+	for i, req := range reqs {
+		names[i] = req.Name
+	}
+
+	return names
+}
+
+func TestDependencyOrder(t *testing.T) {
+	tests := []struct {
+		name        string
+		reqs        []NewContainerRequest
+		expected    []string
+		expectedErr string
+	}{
+		{
+			name: "no dependencies keeps input order",
+			reqs: []NewContainerRequest{
+				{Name: "a"},
+				{Name: "b"},
+			},
+			expected: []string{"a", "b"},
+		},
+		{
+			name: "dependency starts before its dependent",
+			reqs: []NewContainerRequest{
+				{Name: "app", DependsOn: []string{"db"}},
+				{Name: "db"},
+			},
+			expected: []string{"db", "app"},
+		},
+		{
+			name: "transitive dependencies resolve in order",
+			reqs: []NewContainerRequest{
+				{Name: "c", DependsOn: []string{"b"}},
+				{Name: "b", DependsOn: []string{"a"}},
+				{Name: "a"},
+			},
+			expected: []string{"a", "b", "c"},
+		},
+		{
+			name: "duplicate name is an error",
+			reqs: []NewContainerRequest{
+				{Name: "a"},
+				{Name: "a"},
+			},
+			expectedErr: `duplicate container name "a" in group`,
+		},
+		{
+			name: "unknown DependsOn target is an error",
+			reqs: []NewContainerRequest{
+				{Name: "a", DependsOn: []string{"missing"}},
+			},
+			expectedErr: `unknown DependsOn target "missing"`,
+		},
+		{
+			name: "dependency cycle is an error",
+			reqs: []NewContainerRequest{
+				{Name: "a", DependsOn: []string{"b"}},
+				{Name: "b", DependsOn: []string{"a"}},
+			},
+			expectedErr: `dependency cycle detected at "a"`,
+		},
+		{
+			name: "missing name is an error",
+			reqs: []NewContainerRequest{
+				{Name: ""},
+			},
+			expectedErr: "every container in a group must have a Name",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			order, err := dependencyOrder(tt.reqs)
+
+			if tt.expectedErr != "" {
+				assert.EqualError(t, err, tt.expectedErr)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, namesOf(order))
+		})
+	}
+}
@@ -0,0 +1,184 @@
+package docker
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"sync"
+
+	"github.com/cenkalti/backoff/v4"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/mount"
+	"github.com/docker/docker/client"
+	"github.com/docker/go-connections/nat"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// reaperImage is the sidecar testcontainers-go uses to guarantee container cleanup even
+// when the test process dies without running its defers.
+const reaperImage = "testcontainers/ryuk:0.5.1"
+
+// reaperSessionLabel is applied to every container StartContainer creates (unless
+// SkipReaper is set), so the reaper knows what to remove.
+const reaperSessionLabel = "go-docker-session"
+
+// reaper holds the connection to a running Ryuk sidecar. As long as the connection stays
+// open, Ryuk keeps watching for our session's containers; when it drops (process exit,
+// crash, or an explicit PruneSession), Ryuk removes everything labeled with our session.
+type reaper struct {
+	sessionID string
+	conn      net.Conn
+}
+
+var (
+	reaperMu     sync.Mutex
+	activeReaper *reaper
+)
+
+// sessionLabels returns the labels StartContainer should stamp onto a new container so the
+// reaper can find it later. It lazily starts the reaper sidecar on first use.
+func sessionLabels(ctx context.Context, cli *client.Client) (map[string]string, error) {
+	r, err := getReaper(ctx, cli)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]string{reaperSessionLabel: r.sessionID}, nil
+}
+
+func getReaper(ctx context.Context, cli *client.Client) (*reaper, error) {
+	reaperMu.Lock()
+	defer reaperMu.Unlock()
+
+	if activeReaper != nil {
+		return activeReaper, nil
+	}
+
+	sessionID := uuid.New().String()
+
+	if _, _, err := cli.ImageInspectWithRaw(ctx, reaperImage); err != nil {
+		if err := pullImage(cli, reaperImage); err != nil {
+			return nil, errors.Wrap(err, "pulling reaper image")
+		}
+	}
+
+	c := container.Config{
+		Image:        reaperImage,
+		ExposedPorts: nat.PortSet{"8080/tcp": {}},
+	}
+
+	h := container.HostConfig{
+		AutoRemove: true,
+		Mounts: []mount.Mount{{
+			Type:   mount.TypeBind,
+			Source: "/var/run/docker.sock",
+			Target: "/var/run/docker.sock",
+		}},
+		PortBindings: nat.PortMap{
+			"8080/tcp": []nat.PortBinding{{HostIP: "", HostPort: ""}},
+		},
+	}
+
+	res, err := cli.ContainerCreate(ctx, &c, &h, nil, "go-docker-reaper-"+sessionID)
+	if err != nil {
+		return nil, errors.Wrap(err, "creating reaper container")
+	}
+
+	if err := cli.ContainerStart(ctx, res.ID, types.ContainerStartOptions{}); err != nil {
+		return nil, errors.Wrap(err, "starting reaper container")
+	}
+
+	inspect, err := cli.ContainerInspect(ctx, res.ID)
+	if err != nil {
+		return nil, errors.Wrap(err, "inspecting reaper container")
+	}
+
+	bindings := inspect.NetworkSettings.Ports["8080/tcp"]
+	if len(bindings) == 0 {
+		return nil, errors.New("reaper container did not publish its port")
+	}
+
+	var conn net.Conn
+	err = backoff.Retry(func() error {
+		var dialErr error
+		conn, dialErr = net.Dial("tcp", net.JoinHostPort("localhost", bindings[0].HostPort))
+		return dialErr
+	}, newBackOff(ctx))
+	if err != nil {
+		return nil, errors.Wrap(err, "connecting to reaper")
+	}
+
+	if _, err := fmt.Fprintf(conn, "label=%s=%s\n", reaperSessionLabel, sessionID); err != nil {
+		_ = conn.Close()
+		return nil, errors.Wrap(err, "registering session filter with reaper")
+	}
+
+	// Ryuk acknowledges the filter with a line of its own before it starts watching.
+	if _, err := bufio.NewReader(conn).ReadString('\n'); err != nil {
+		_ = conn.Close()
+		return nil, errors.Wrap(err, "waiting for reaper acknowledgement")
+	}
+
+	activeReaper = &reaper{sessionID: sessionID, conn: conn}
+
+	return activeReaper, nil
+}
+
+// PruneSession synchronously removes every container labeled with this process's reaper
+// session: it lists and force-removes them directly, rather than waiting on Ryuk's own
+// reconnection-timeout cleanup, so the containers are actually gone by the time it returns.
+// It then closes the reaper connection, since there's nothing left for Ryuk to watch for
+// this session. Call it once at the end of TestMain for deterministic cleanup instead of
+// waiting for process exit.
+func PruneSession() error {
+	reaperMu.Lock()
+	r := activeReaper
+	reaperMu.Unlock()
+
+	if r == nil {
+		return nil
+	}
+
+	cli, err := client.NewEnvClient()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := cli.Close(); err != nil {
+			logrus.Debugf("Unable to close docker client %v", err)
+		}
+	}()
+
+	ctx := context.Background()
+
+	containers, err := cli.ContainerList(ctx, types.ContainerListOptions{
+		All:     true,
+		Filters: filters.NewArgs(filters.Arg("label", reaperSessionLabel+"="+r.sessionID)),
+	})
+	if err != nil {
+		return errors.Wrap(err, "listing session containers")
+	}
+
+	for _, c := range containers {
+		err := cli.ContainerRemove(ctx, c.ID, types.ContainerRemoveOptions{
+			RemoveVolumes: true,
+			Force:         true,
+		})
+		if err != nil {
+			logrus.Debugf("Unable to remove session container %s: %v", c.ID, err)
+		}
+	}
+
+	reaperMu.Lock()
+	defer reaperMu.Unlock()
+
+	err = activeReaper.conn.Close()
+	activeReaper = nil
+
+	return err
+}
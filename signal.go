@@ -0,0 +1,104 @@
+package docker
+
+import (
+	"os"
+	"os/signal"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// registry tracks every live *DockerContainer by ID, so EnableSignalTrap can find what needs
+// cleaning up without the caller threading container references through to wherever the
+// signal is handled.
+var (
+	registryMu sync.Mutex
+	registry   = map[string]*DockerContainer{}
+)
+
+func registerContainer(d *DockerContainer) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	registry[d.id] = d
+}
+
+func unregisterContainer(d *DockerContainer) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	delete(registry, d.id)
+}
+
+// EnableSignalTrap installs a SIGINT/SIGTERM handler, similar to the Docker engine's own
+// signal trap, so that a developer hitting Ctrl-C during a local test run gets their
+// containers cleaned up immediately instead of relying on the reaper sidecar to eventually
+// notice the process is gone. Call it once, typically from TestMain.
+//
+// On the first signal, every live container is closed concurrently (with a 5s grace
+// period each) before the process exits with 128+signal. Hitting the signal a third time
+// skips cleanup entirely and exits immediately, in case cleanup itself is stuck. Under
+// DEBUG=1, SIGQUIT is also trapped for an immediate, no-cleanup exit.
+func EnableSignalTrap() {
+	sigs := []os.Signal{syscall.SIGINT, syscall.SIGTERM}
+	if os.Getenv("DEBUG") == "1" {
+		sigs = append(sigs, syscall.SIGQUIT)
+	}
+
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, sigs...)
+
+	var received int32
+
+	go func() {
+		for sig := range ch {
+			if sig == syscall.SIGQUIT {
+				logrus.Warn("received SIGQUIT, exiting immediately without cleanup")
+				os.Exit(1)
+			}
+
+			n := atomic.AddInt32(&received, 1)
+			exitCode := 128 + int(sig.(syscall.Signal))
+
+			if n >= 3 {
+				logrus.Warn("received signal a third time, skipping cleanup and exiting immediately")
+				os.Exit(exitCode)
+			}
+
+			if n == 1 {
+				registryMu.Lock()
+				pending := len(registry)
+				registryMu.Unlock()
+
+				logrus.Infof("received %v, closing %d container(s) before exit", sig, pending)
+
+				go func() {
+					closeRegisteredContainers(5 * time.Second)
+					os.Exit(exitCode)
+				}()
+			}
+		}
+	}()
+}
+
+func closeRegisteredContainers(timeout time.Duration) {
+	registryMu.Lock()
+	containers := make([]*DockerContainer, 0, len(registry))
+	for _, c := range registry {
+		containers = append(containers, c)
+	}
+	registryMu.Unlock()
+
+	var wg sync.WaitGroup
+	for _, c := range containers {
+		wg.Add(1)
+		go func(c *DockerContainer) {
+			defer wg.Done()
+			c.CloseWithTimeout(timeout)
+		}(c)
+	}
+	wg.Wait()
+}
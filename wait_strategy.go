@@ -0,0 +1,201 @@
+package docker
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"net/http"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/cenkalti/backoff/v4"
+	"github.com/docker/docker/api/types"
+	"github.com/pkg/errors"
+)
+
+// defaultWaitTimeout bounds how long StartContainer will block on any WaitStrategy.
+// WithStartupTimeout can shorten this bound but cannot extend it.
+const defaultWaitTimeout = 60 * time.Second
+
+// WaitStrategy determines when a freshly started container is actually ready to be used,
+// as opposed to merely running. StartContainer blocks on WaitUntilReady before returning.
+type WaitStrategy interface {
+	WaitUntilReady(ctx context.Context, c *DockerContainer) error
+}
+
+// WithStartupTimeout wraps a WaitStrategy so it gives up after the given duration rather
+// than being bounded only by the context StartContainer passes in.
+func WithStartupTimeout(strategy WaitStrategy, timeout time.Duration) WaitStrategy {
+	return &timeoutWaitStrategy{strategy: strategy, timeout: timeout}
+}
+
+type timeoutWaitStrategy struct {
+	strategy WaitStrategy
+	timeout  time.Duration
+}
+
+func (w *timeoutWaitStrategy) WaitUntilReady(ctx context.Context, c *DockerContainer) error {
+	ctx, cancel := context.WithTimeout(ctx, w.timeout)
+	defer cancel()
+
+	return w.strategy.WaitUntilReady(ctx, c)
+}
+
+// newBackOff builds the exponential backoff shared by every built-in wait strategy: starting
+// at 50ms and growing to roughly a second between polls, bounded by the caller's context.
+func newBackOff(ctx context.Context) backoff.BackOffContext {
+	b := backoff.NewExponentialBackOff()
+	b.InitialInterval = 50 * time.Millisecond
+	b.MaxInterval = time.Second
+	b.MaxElapsedTime = 0 // bounded by ctx instead of the backoff's own clock
+
+	return backoff.WithContext(b, ctx)
+}
+
+// portWaitStrategy polls until the given container port is accepting TCP connections.
+type portWaitStrategy struct {
+	port ContainerPort
+}
+
+// ForListeningPort waits until the supplied container port has a mapped host port that is
+// accepting TCP connections.
+func ForListeningPort(port ContainerPort) WaitStrategy {
+	return &portWaitStrategy{port: port}
+}
+
+func (w *portWaitStrategy) WaitUntilReady(ctx context.Context, c *DockerContainer) error {
+	return backoff.Retry(func() error {
+		host, err := c.Host(ctx)
+		if err != nil {
+			return err
+		}
+
+		mapped := c.PortMapping(int(w.port))
+
+		conn, err := net.DialTimeout("tcp", net.JoinHostPort(host, strconv.Itoa(int(mapped))), 500*time.Millisecond)
+		if err != nil {
+			return err
+		}
+
+		return conn.Close()
+	}, newBackOff(ctx))
+}
+
+// logWaitStrategy polls until a regexp has matched a given number of lines in the container's
+// combined stdout/stderr log stream.
+type logWaitStrategy struct {
+	pattern     *regexp.Regexp
+	occurrences int
+}
+
+// ForLog waits until `pattern` has matched at least `occurrences` log lines. Use occurrences
+// of 1 for the common "wait for a single readiness line" case.
+func ForLog(pattern string, occurrences int) WaitStrategy {
+	return &logWaitStrategy{pattern: regexp.MustCompile(pattern), occurrences: occurrences}
+}
+
+func (w *logWaitStrategy) WaitUntilReady(ctx context.Context, c *DockerContainer) error {
+	return backoff.Retry(func() error {
+		reader, err := c.client.ContainerLogs(ctx, c.id, types.ContainerLogsOptions{
+			ShowStdout: true,
+			ShowStderr: true,
+			Follow:     true,
+		})
+		if err != nil {
+			return err
+		}
+		defer func() {
+			_ = reader.Close()
+		}()
+
+		seen := 0
+		scanner := bufio.NewScanner(reader)
+		for scanner.Scan() {
+			if w.pattern.MatchString(scanner.Text()) {
+				seen++
+				if seen >= w.occurrences {
+					return nil
+				}
+			}
+
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			default:
+			}
+		}
+
+		if err := scanner.Err(); err != nil {
+			return err
+		}
+
+		return errors.Errorf("log stream ended before %q matched %d time(s)", w.pattern, w.occurrences)
+	}, newBackOff(ctx))
+}
+
+// httpWaitStrategy polls a container port with an HTTP GET until it returns an acceptable
+// status code.
+type httpWaitStrategy struct {
+	port       ContainerPort
+	path       string
+	statusCode int
+}
+
+// ForHTTP waits until an HTTP GET to `path` on `port` returns the given status code.
+func ForHTTP(port ContainerPort, path string, statusCode int) WaitStrategy {
+	return &httpWaitStrategy{port: port, path: path, statusCode: statusCode}
+}
+
+func (w *httpWaitStrategy) WaitUntilReady(ctx context.Context, c *DockerContainer) error {
+	return backoff.Retry(func() error {
+		endpoint, err := c.Endpoint(int(w.port), "http")
+		if err != nil {
+			return err
+		}
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint+w.path, nil)
+		if err != nil {
+			return err
+		}
+
+		res, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			_ = res.Body.Close()
+		}()
+
+		if res.StatusCode != w.statusCode {
+			return errors.Errorf("expected status %d, got %d", w.statusCode, res.StatusCode)
+		}
+
+		return nil
+	}, newBackOff(ctx))
+}
+
+// execWaitStrategy polls by running a command inside the container until it exits 0.
+type execWaitStrategy struct {
+	cmd []string
+}
+
+// ForExec waits until running `cmd` inside the container exits with code 0.
+func ForExec(cmd []string) WaitStrategy {
+	return &execWaitStrategy{cmd: cmd}
+}
+
+func (w *execWaitStrategy) WaitUntilReady(ctx context.Context, c *DockerContainer) error {
+	return backoff.Retry(func() error {
+		exitCode, _, _, err := c.Exec(ctx, w.cmd, ExecOptions{})
+		if err != nil {
+			return err
+		}
+
+		if exitCode != 0 {
+			return errors.Errorf("command %v exited %d", w.cmd, exitCode)
+		}
+
+		return nil
+	}, newBackOff(ctx))
+}